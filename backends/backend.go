@@ -0,0 +1,78 @@
+// Package backends defines the storage abstraction that the migration
+// pipeline moves objects through. Each concrete backend (s3, minio, local)
+// implements Backend so the same pipeline can copy between any combination
+// of them.
+package backends
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotExist is returned by Stat when the key does not exist at the
+// backend.
+var ErrNotExist = errors.New("backends: object does not exist")
+
+// ObjectInfo is the metadata needed to decide whether a transfer can be
+// skipped, to report progress, and, when PreserveMetadata is enabled, to
+// recreate the object faithfully at the destination.
+type ObjectInfo struct {
+	ETag            string
+	Size            int64
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+	Metadata        map[string]string
+	ACL             string
+	StorageClass    string
+}
+
+// Backend is a storage system that objects can be listed, read from, and
+// written to by key.
+type Backend interface {
+	// List calls fn for every key under prefix. Returning an error from fn
+	// stops iteration and List returns that error.
+	List(ctx context.Context, prefix string, fn func(key string, info ObjectInfo) error) error
+
+	// Get opens the object for reading. The caller must close the returned
+	// ReadCloser.
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+
+	// Put writes body as key, replacing any existing object.
+	Put(ctx context.Context, key string, body io.Reader, info ObjectInfo) error
+
+	// Stat returns the current metadata for key, or ErrNotExist if it is
+	// not present.
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+
+	// Delete removes key. It is not an error if key does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// CopySourcer is an optional capability: backends whose provider supports
+// copying an object between buckets without routing the bytes through this
+// process implement it. srcBucket/srcKey identify the object on the same
+// provider as the receiver's own bucket. Only info.ACL and
+// info.StorageClass are consulted, matching the destination overrides
+// copyObject applies for the streamed transfer paths.
+type CopySourcer interface {
+	CopyObject(ctx context.Context, srcBucket, srcKey, dstKey string, size int64, info ObjectInfo) error
+}
+
+// Config is the user-facing configuration for a single backend endpoint,
+// shared by source and destination.
+type Config struct {
+	Type        string   `yaml:"type"`
+	AccessKey   string   `yaml:"accessKey"`
+	SecretKey   string   `yaml:"secretKey"`
+	Endpoint    string   `yaml:"endpoint"`
+	Bucket      string   `yaml:"bucket"`
+	Region      string   `yaml:"region"`
+	Path        string   `yaml:"path"`
+	PartSize    int64    `yaml:"partSize"`
+	Concurrency int      `yaml:"concurrency"`
+	Prefix      string   `yaml:"prefix"`
+	Include     []string `yaml:"include"`
+	Exclude     []string `yaml:"exclude"`
+}
@@ -0,0 +1,153 @@
+// Package local implements backends.Backend against a directory on the
+// local filesystem, rooted at Config.Path. The filesystem has no concept
+// of content-type, ACLs, or user metadata, so ObjectInfo only ever
+// carries Size and a content-hash ETag here; preserveMetadata has no
+// effect when local is on either side of a transfer.
+package local
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/xfy777/S3-Migration/backends"
+)
+
+// Backend is a backends.Backend backed by a directory tree. Keys map to
+// slash-separated paths relative to root.
+type Backend struct {
+	root string
+}
+
+// New builds a Backend rooted at cfg.Path, creating the directory if it
+// does not already exist.
+func New(cfg backends.Config) (*Backend, error) {
+	if err := os.MkdirAll(cfg.Path, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &Backend{root: cfg.Path}, nil
+}
+
+func (b *Backend) path(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+func (b *Backend) List(ctx context.Context, prefix string, fn func(key string, info backends.ObjectInfo) error) error {
+	return filepath.Walk(b.root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		relPath, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relPath)
+		if prefix != "" && !hasPrefix(key, prefix) {
+			return nil
+		}
+
+		etag, err := md5ETag(path)
+		if err != nil {
+			return err
+		}
+
+		return fn(key, backends.ObjectInfo{ETag: etag, Size: fi.Size()})
+	})
+}
+
+func (b *Backend) Get(ctx context.Context, key string) (io.ReadCloser, backends.ObjectInfo, error) {
+	path := b.path(key)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	etag, err := md5ETag(path)
+	if err != nil {
+		file.Close()
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	return file, backends.ObjectInfo{ETag: etag, Size: fi.Size()}, nil
+}
+
+func (b *Backend) Put(ctx context.Context, key string, body io.Reader, info backends.ObjectInfo) error {
+	dest := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, body)
+	return err
+}
+
+func (b *Backend) Stat(ctx context.Context, key string) (backends.ObjectInfo, error) {
+	path := b.path(key)
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return backends.ObjectInfo{}, backends.ErrNotExist
+		}
+		return backends.ObjectInfo{}, err
+	}
+
+	etag, err := md5ETag(path)
+	if err != nil {
+		return backends.ObjectInfo{}, err
+	}
+
+	return backends.ObjectInfo{ETag: etag, Size: fi.Size()}, nil
+}
+
+func (b *Backend) Delete(ctx context.Context, key string) error {
+	err := os.Remove(b.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func hasPrefix(key, prefix string) bool {
+	return len(key) >= len(prefix) && key[:len(prefix)] == prefix
+}
+
+// md5ETag hashes the file at path the same way S3 computes the ETag of a
+// single-part object, so a staged file's ObjectInfo.ETag can be compared
+// directly against the real destination's ETag in resolveSkip.
+func md5ETag(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
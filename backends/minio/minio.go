@@ -0,0 +1,109 @@
+// Package minio implements backends.Backend against a MinIO (or other
+// S3-compatible) endpoint using minio-go, which handles multipart uploads
+// above its internal threshold and region/redirect handling automatically.
+package minio
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/xfy777/S3-Migration/backends"
+)
+
+// Backend is a backends.Backend backed by a single MinIO bucket.
+type Backend struct {
+	client *minio.Client
+	bucket string
+}
+
+// New builds a Backend from a backends.Config.
+func New(cfg backends.Config) (*Backend, error) {
+	endpoint := cfg.Endpoint
+	secure := true
+	if strings.HasPrefix(endpoint, "http://") {
+		secure = false
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+	} else if strings.HasPrefix(endpoint, "https://") {
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: secure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (b *Backend) List(ctx context.Context, prefix string, fn func(key string, info backends.ObjectInfo) error) error {
+	for object := range b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return object.Err
+		}
+		info := backends.ObjectInfo{ETag: object.ETag, Size: object.Size}
+		if err := fn(object.Key, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Backend) Get(ctx context.Context, key string) (io.ReadCloser, backends.ObjectInfo, error) {
+	object, err := b.client.GetObject(ctx, b.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	return object, objectInfoFromStat(stat), nil
+}
+
+func (b *Backend) Put(ctx context.Context, key string, body io.Reader, info backends.ObjectInfo) error {
+	opts := minio.PutObjectOptions{
+		ContentType:     info.ContentType,
+		ContentEncoding: info.ContentEncoding,
+		CacheControl:    info.CacheControl,
+		UserMetadata:    info.Metadata,
+		StorageClass:    info.StorageClass,
+	}
+	_, err := b.client.PutObject(ctx, b.bucket, key, body, info.Size, opts)
+	return err
+}
+
+func (b *Backend) Stat(ctx context.Context, key string) (backends.ObjectInfo, error) {
+	stat, err := b.client.StatObject(ctx, b.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return backends.ObjectInfo{}, backends.ErrNotExist
+		}
+		return backends.ObjectInfo{}, err
+	}
+	return objectInfoFromStat(stat), nil
+}
+
+func objectInfoFromStat(stat minio.ObjectInfo) backends.ObjectInfo {
+	return backends.ObjectInfo{
+		ETag:         stat.ETag,
+		Size:         stat.Size,
+		ContentType:  stat.ContentType,
+		Metadata:     stat.UserMetadata,
+		StorageClass: stat.StorageClass,
+	}
+}
+
+func (b *Backend) Delete(ctx context.Context, key string) error {
+	return b.client.RemoveObject(ctx, b.bucket, key, minio.RemoveObjectOptions{})
+}
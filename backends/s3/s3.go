@@ -0,0 +1,302 @@
+// Package s3 implements backends.Backend against an S3 (or S3-compatible)
+// bucket using aws-sdk-go.
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/xfy777/S3-Migration/backends"
+)
+
+const (
+	defaultPartSize    = 5 * 1024 * 1024 // 5 MB
+	defaultConcurrency = 5
+
+	// maxSingleCopySize is the largest object CopyObject will handle in one
+	// request; S3 requires UploadPartCopy above this.
+	maxSingleCopySize = 5 * 1024 * 1024 * 1024 // 5 GB
+	// copyPartSize is the chunk size used for each UploadPartCopy request.
+	copyPartSize = 1 * 1024 * 1024 * 1024 // 1 GB
+)
+
+// Backend is a backends.Backend backed by a single S3 bucket.
+type Backend struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+// New builds a Backend from a backends.Config. Endpoint may be left empty
+// to use AWS's default S3 endpoint for the region, or set to point at an
+// S3-compatible endpoint.
+func New(cfg backends.Config) (*Backend, error) {
+	awsConfig := &aws.Config{
+		Endpoint:    aws.String(cfg.Endpoint),
+		Credentials: credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""),
+		Region:      aws.String(cfg.Region),
+	}
+
+	sess := session.Must(session.NewSession(awsConfig))
+
+	partSize := cfg.PartSize
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = concurrency
+	})
+
+	return &Backend{
+		client:   s3.New(sess),
+		uploader: uploader,
+		bucket:   cfg.Bucket,
+	}, nil
+}
+
+func (b *Backend) List(ctx context.Context, prefix string, fn func(key string, info backends.ObjectInfo) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	var listErr error
+	err := b.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			info := backends.ObjectInfo{
+				ETag: strings.Trim(aws.StringValue(object.ETag), `"`),
+				Size: aws.Int64Value(object.Size),
+			}
+			if err := fn(aws.StringValue(object.Key), info); err != nil {
+				listErr = err
+				return false
+			}
+		}
+		return true
+	})
+	if listErr != nil {
+		return listErr
+	}
+	return err
+}
+
+// Get opens a single unbuffered GetObject stream. copyObject and the
+// streaming/server-side-copy migration modes depend on Get returning bytes
+// as they arrive rather than staging the whole object to disk first, so
+// this deliberately does not route through s3manager.Downloader: its
+// Download method requires an io.WriterAt, which only a fully-buffered
+// destination (memory or a temp file) can satisfy.
+func (b *Backend) Get(ctx context.Context, key string) (io.ReadCloser, backends.ObjectInfo, error) {
+	output, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, backends.ObjectInfo{}, err
+	}
+
+	info := backends.ObjectInfo{
+		ETag:            strings.Trim(aws.StringValue(output.ETag), `"`),
+		Size:            aws.Int64Value(output.ContentLength),
+		ContentType:     aws.StringValue(output.ContentType),
+		ContentEncoding: aws.StringValue(output.ContentEncoding),
+		CacheControl:    aws.StringValue(output.CacheControl),
+		Metadata:        aws.StringValueMap(output.Metadata),
+		StorageClass:    aws.StringValue(output.StorageClass),
+	}
+	return output.Body, info, nil
+}
+
+func (b *Backend) Put(ctx context.Context, key string, body io.Reader, info backends.ObjectInfo) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}
+	if info.ContentType != "" {
+		input.ContentType = aws.String(info.ContentType)
+	}
+	if info.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(info.ContentEncoding)
+	}
+	if info.CacheControl != "" {
+		input.CacheControl = aws.String(info.CacheControl)
+	}
+	if len(info.Metadata) > 0 {
+		input.Metadata = aws.StringMap(info.Metadata)
+	}
+	if info.ACL != "" {
+		input.ACL = aws.String(info.ACL)
+	}
+	if info.StorageClass != "" {
+		input.StorageClass = aws.String(info.StorageClass)
+	}
+
+	_, err := b.uploader.UploadWithContext(ctx, input)
+	return err
+}
+
+func (b *Backend) Stat(ctx context.Context, key string) (backends.ObjectInfo, error) {
+	head, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isNotFoundErr(err) {
+			return backends.ObjectInfo{}, backends.ErrNotExist
+		}
+		return backends.ObjectInfo{}, err
+	}
+
+	return backends.ObjectInfo{
+		ETag:            strings.Trim(aws.StringValue(head.ETag), `"`),
+		Size:            aws.Int64Value(head.ContentLength),
+		ContentType:     aws.StringValue(head.ContentType),
+		ContentEncoding: aws.StringValue(head.ContentEncoding),
+		CacheControl:    aws.StringValue(head.CacheControl),
+		Metadata:        aws.StringValueMap(head.Metadata),
+		StorageClass:    aws.StringValue(head.StorageClass),
+	}, nil
+}
+
+func (b *Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// CopyObject copies srcKey from srcBucket (on this same provider/account)
+// directly into dstKey in b's bucket, using S3's server-side CopyObject for
+// objects up to 5 GB and multipart UploadPartCopy above that, so the bytes
+// never pass through this process. info.ACL and info.StorageClass, if set,
+// are applied to the copied object.
+func (b *Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstKey string, size int64, info backends.ObjectInfo) error {
+	copySource := aws.String(encodeCopySource(srcBucket, srcKey))
+
+	if size <= maxSingleCopySize {
+		input := &s3.CopyObjectInput{
+			Bucket:     aws.String(b.bucket),
+			Key:        aws.String(dstKey),
+			CopySource: copySource,
+		}
+		if info.ACL != "" {
+			input.ACL = aws.String(info.ACL)
+		}
+		if info.StorageClass != "" {
+			input.StorageClass = aws.String(info.StorageClass)
+		}
+
+		_, err := b.client.CopyObjectWithContext(ctx, input)
+		return err
+	}
+
+	return b.multipartCopy(ctx, copySource, dstKey, size, info)
+}
+
+func (b *Backend) multipartCopy(ctx context.Context, copySource *string, dstKey string, size int64, info backends.ObjectInfo) error {
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(dstKey),
+	}
+	if info.ACL != "" {
+		createInput.ACL = aws.String(info.ACL)
+	}
+	if info.StorageClass != "" {
+		createInput.StorageClass = aws.String(info.StorageClass)
+	}
+
+	created, err := b.client.CreateMultipartUploadWithContext(ctx, createInput)
+	if err != nil {
+		return fmt.Errorf("creating multipart upload: %w", err)
+	}
+	uploadID := created.UploadId
+
+	var completedParts []*s3.CompletedPart
+	partNumber := int64(1)
+	for start := int64(0); start < size; start += copyPartSize {
+		end := start + copyPartSize - 1
+		if end >= size {
+			end = size - 1
+		}
+
+		part, err := b.client.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(b.bucket),
+			Key:             aws.String(dstKey),
+			UploadId:        uploadID,
+			PartNumber:      aws.Int64(partNumber),
+			CopySource:      copySource,
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+		if err != nil {
+			b.abortMultipartCopy(dstKey, uploadID)
+			return fmt.Errorf("copying part %d: %w", partNumber, err)
+		}
+
+		completedParts = append(completedParts, &s3.CompletedPart{
+			ETag:       part.CopyPartResult.ETag,
+			PartNumber: aws.Int64(partNumber),
+		})
+		partNumber++
+	}
+
+	_, err = b.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(b.bucket),
+		Key:             aws.String(dstKey),
+		UploadId:        uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completedParts},
+	})
+	if err != nil {
+		b.abortMultipartCopy(dstKey, uploadID)
+		return fmt.Errorf("completing multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (b *Backend) abortMultipartCopy(dstKey string, uploadID *string) {
+	_, _ = b.client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(b.bucket),
+		Key:      aws.String(dstKey),
+		UploadId: uploadID,
+	})
+}
+
+// encodeCopySource builds the "bucket/key" value CopyObject/UploadPartCopy
+// expect in x-amz-copy-source, URL-encoding each path segment of key while
+// leaving the "/" separators intact.
+func encodeCopySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
+
+func isNotFoundErr(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchKey, "NotFound":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Package metrics exposes the migration's Prometheus counters and a
+// /metrics HTTP endpoint, independent of which Reporter the operator has
+// chosen for console output.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// ObjectsCopied counts objects successfully written to the
+	// destination.
+	ObjectsCopied = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "objects_copied_total",
+		Help: "Total number of objects successfully copied to the destination.",
+	})
+
+	// BytesCopied counts bytes successfully written to the destination.
+	BytesCopied = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bytes_copied_total",
+		Help: "Total number of bytes successfully copied to the destination.",
+	})
+
+	// Errors counts transfer failures, labeled by the stage that failed
+	// (e.g. "get", "put", "copy").
+	Errors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "errors_total",
+		Help: "Total number of transfer errors, by stage.",
+	}, []string{"stage"})
+
+	// InFlight is the number of objects currently being transferred.
+	InFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "objects_in_flight",
+		Help: "Number of objects currently being transferred.",
+	})
+)
+
+// Serve starts an HTTP server exposing /metrics on addr in the
+// background, shutting it down when ctx is canceled.
+func Serve(ctx context.Context, addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Println("Error serving metrics:", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	return srv
+}
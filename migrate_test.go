@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/xfy777/S3-Migration/backends"
+)
+
+// fakeBackend is a minimal backends.Backend backed by an in-memory map,
+// used to drive resolveSkip without a real storage provider.
+type fakeBackend struct {
+	objects map[string]backends.ObjectInfo
+}
+
+func (f *fakeBackend) List(ctx context.Context, prefix string, fn func(key string, info backends.ObjectInfo) error) error {
+	for key, info := range f.objects {
+		if err := fn(key, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeBackend) Get(ctx context.Context, key string) (io.ReadCloser, backends.ObjectInfo, error) {
+	info, ok := f.objects[key]
+	if !ok {
+		return nil, backends.ObjectInfo{}, backends.ErrNotExist
+	}
+	return io.NopCloser(nil), info, nil
+}
+
+func (f *fakeBackend) Put(ctx context.Context, key string, body io.Reader, info backends.ObjectInfo) error {
+	f.objects[key] = info
+	return nil
+}
+
+func (f *fakeBackend) Stat(ctx context.Context, key string) (backends.ObjectInfo, error) {
+	info, ok := f.objects[key]
+	if !ok {
+		return backends.ObjectInfo{}, backends.ErrNotExist
+	}
+	return info, nil
+}
+
+func (f *fakeBackend) Delete(ctx context.Context, key string) error {
+	delete(f.objects, key)
+	return nil
+}
+
+func TestResolveSkip(t *testing.T) {
+	srcInfo := backends.ObjectInfo{ETag: "abc", Size: 100}
+
+	t.Run("resumes from manifest when done and unchanged", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{}}
+		manifest := &Manifest{objects: map[string]*ObjectState{
+			"key": {Status: statusDone, ETag: "abc", Size: 100},
+		}}
+
+		skip, err := resolveSkip(context.Background(), dst, "key", srcInfo, manifest, true, overwriteIfDifferent)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if !skip {
+			t.Fatal("expected skip=true for a manifest entry matching ETag and size")
+		}
+	})
+
+	t.Run("does not resume when manifest entry is stale", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{}}
+		manifest := &Manifest{objects: map[string]*ObjectState{
+			"key": {Status: statusDone, ETag: "old", Size: 100},
+		}}
+
+		skip, err := resolveSkip(context.Background(), dst, "key", srcInfo, manifest, true, overwriteIfDifferent)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if skip {
+			t.Fatal("expected skip=false when the manifest ETag no longer matches the source")
+		}
+	})
+
+	t.Run("overwriteAlways never skips", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{
+			"key": srcInfo,
+		}}
+
+		skip, err := resolveSkip(context.Background(), dst, "key", srcInfo, nil, false, overwriteAlways)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if skip {
+			t.Fatal("expected skip=false with overwrite=always even when the destination matches")
+		}
+	})
+
+	t.Run("overwriteNever skips whenever the destination exists", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{
+			"key": {ETag: "different", Size: 1},
+		}}
+
+		skip, err := resolveSkip(context.Background(), dst, "key", srcInfo, nil, false, overwriteNever)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if !skip {
+			t.Fatal("expected skip=true with overwrite=never whenever the destination already has the key")
+		}
+	})
+
+	t.Run("overwriteNever does not skip when destination is missing", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{}}
+
+		skip, err := resolveSkip(context.Background(), dst, "key", srcInfo, nil, false, overwriteNever)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if skip {
+			t.Fatal("expected skip=false with overwrite=never when the destination doesn't have the key yet")
+		}
+	})
+
+	t.Run("ifDifferent skips only when ETag and size match", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{
+			"same": srcInfo,
+			"diff": {ETag: "xyz", Size: 100},
+		}}
+
+		skip, err := resolveSkip(context.Background(), dst, "same", srcInfo, nil, false, overwriteIfDifferent)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if !skip {
+			t.Fatal("expected skip=true when destination ETag and size match the source")
+		}
+
+		skip, err = resolveSkip(context.Background(), dst, "diff", srcInfo, nil, false, overwriteIfDifferent)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if skip {
+			t.Fatal("expected skip=false when destination ETag differs from the source")
+		}
+	})
+
+	t.Run("ifDifferent does not skip when destination is missing", func(t *testing.T) {
+		dst := &fakeBackend{objects: map[string]backends.ObjectInfo{}}
+
+		skip, err := resolveSkip(context.Background(), dst, "missing", srcInfo, nil, false, overwriteIfDifferent)
+		if err != nil {
+			t.Fatalf("resolveSkip returned error: %v", err)
+		}
+		if skip {
+			t.Fatal("expected skip=false when the destination has no such key")
+		}
+	})
+}
+
+func TestMatchesFilters(t *testing.T) {
+	cases := []struct {
+		name    string
+		key     string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"no filters matches everything", "a/b.txt", nil, nil, true},
+		{"include matches", "a/b.txt", []string{"a/*.txt"}, nil, true},
+		{"include does not match", "a/b.txt", []string{"*.csv"}, nil, false},
+		{"exclude wins over include", "a/b.txt", []string{"a/*.txt"}, []string{"a/*.txt"}, false},
+		{"exclude only", "a/b.txt", nil, []string{"a/*.txt"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matchesFilters(c.key, c.include, c.exclude); got != c.want {
+				t.Errorf("matchesFilters(%q, %v, %v) = %v, want %v", c.key, c.include, c.exclude, got, c.want)
+			}
+		})
+	}
+}
+
+func TestResolveMode(t *testing.T) {
+	t.Run("explicit mode is always honored", func(t *testing.T) {
+		config := Config{Mode: modeStreaming}
+		if got := resolveMode(config); got != modeStreaming {
+			t.Errorf("resolveMode() = %q, want %q", got, modeStreaming)
+		}
+	})
+
+	t.Run("same S3 endpoint auto-selects server-side-copy", func(t *testing.T) {
+		config := Config{
+			Source:      backends.Config{Type: backendS3, Endpoint: "https://example.com"},
+			Destination: backends.Config{Type: backendS3, Endpoint: "https://example.com"},
+		}
+		if got := resolveMode(config); got != modeServerSideCopy {
+			t.Errorf("resolveMode() = %q, want %q", got, modeServerSideCopy)
+		}
+	})
+
+	t.Run("differing endpoints fall back to staged", func(t *testing.T) {
+		config := Config{
+			Source:      backends.Config{Type: backendS3, Endpoint: "https://a.example.com"},
+			Destination: backends.Config{Type: backendS3, Endpoint: "https://b.example.com"},
+		}
+		if got := resolveMode(config); got != modeStaged {
+			t.Errorf("resolveMode() = %q, want %q", got, modeStaged)
+		}
+	})
+
+	t.Run("non-S3 backend falls back to staged", func(t *testing.T) {
+		config := Config{
+			Source:      backends.Config{Type: backendLocal},
+			Destination: backends.Config{Type: backendS3, Endpoint: "https://example.com"},
+		}
+		if got := resolveMode(config); got != modeStaged {
+			t.Errorf("resolveMode() = %q, want %q", got, modeStaged)
+		}
+	})
+}
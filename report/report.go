@@ -0,0 +1,193 @@
+// Package report provides the Reporter abstraction used to surface
+// migration progress to the operator: a human-readable TTY bar, a
+// machine-readable JSON stream, or nothing at all.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// Mode selects a Reporter implementation via Config.Progress.
+const (
+	ModeBar  = "bar"
+	ModeJSON = "json"
+	ModeNone = "none"
+)
+
+// Reporter is notified of per-object transfer outcomes as a migration
+// runs. Implementations must be safe for concurrent use, since streaming
+// and server-side-copy modes report from multiple goroutines.
+type Reporter interface {
+	// Transferred records key as successfully copied.
+	Transferred(key string, size int64)
+	// Skipped records key as already present at the destination.
+	Skipped(key string)
+	// Failed records a transfer error for key at the given stage (e.g.
+	// "get", "put", "list", "copy").
+	Failed(key, stage string, err error)
+	// Close flushes any buffered output and prints a final summary.
+	Close()
+}
+
+// New builds the Reporter named by mode (bar, json, or none). totalBytes
+// is the known size of the transfer in advance, or -1 if it isn't known
+// yet (e.g. migrateObjects streams its listing rather than collecting it
+// upfront), in which case the bar falls back to a spinner.
+func New(mode string, totalBytes int64) (Reporter, error) {
+	switch mode {
+	case "", ModeNone:
+		return &noopReporter{start: time.Now()}, nil
+	case ModeBar:
+		return newBarReporter(totalBytes), nil
+	case ModeJSON:
+		return &jsonReporter{start: time.Now()}, nil
+	default:
+		return nil, fmt.Errorf("unknown progress mode: %s", mode)
+	}
+}
+
+type barReporter struct {
+	bar   *progressbar.ProgressBar
+	start time.Time
+
+	mu      sync.Mutex
+	objects int64
+	errors  int64
+}
+
+func newBarReporter(totalBytes int64) *barReporter {
+	bar := progressbar.NewOptions64(totalBytes,
+		progressbar.OptionSetDescription("migrating"),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionOnCompletion(func() { fmt.Fprintln(os.Stderr) }),
+	)
+	return &barReporter{bar: bar, start: time.Now()}
+}
+
+func (r *barReporter) Transferred(key string, size int64) {
+	r.mu.Lock()
+	r.objects++
+	r.mu.Unlock()
+	r.bar.Add64(size)
+}
+
+func (r *barReporter) Skipped(key string) {
+	r.mu.Lock()
+	r.objects++
+	r.mu.Unlock()
+}
+
+func (r *barReporter) Failed(key, stage string, err error) {
+	r.mu.Lock()
+	r.errors++
+	r.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "\n%s: %s: %v\n", stage, key, err)
+}
+
+func (r *barReporter) Close() {
+	r.bar.Finish()
+	elapsed := time.Since(r.start)
+	fmt.Printf("Transferred %d objects in %s (%d errors)\n", r.objects, elapsed.Round(time.Second), r.errors)
+}
+
+// jsonReporter emits one JSON object per event on stdout, followed by a
+// summary line on Close, for consumption by log aggregators.
+type jsonReporter struct {
+	start time.Time
+
+	mu         sync.Mutex
+	objects    int64
+	bytesMoved int64
+	errors     int64
+}
+
+type jsonEvent struct {
+	Event string `json:"event"`
+	Key   string `json:"key,omitempty"`
+	Stage string `json:"stage,omitempty"`
+	Size  int64  `json:"size,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (r *jsonReporter) emit(e jsonEvent) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Println(string(data))
+}
+
+func (r *jsonReporter) Transferred(key string, size int64) {
+	r.mu.Lock()
+	r.objects++
+	r.bytesMoved += size
+	r.mu.Unlock()
+	r.emit(jsonEvent{Event: "transferred", Key: key, Size: size})
+}
+
+func (r *jsonReporter) Skipped(key string) {
+	r.mu.Lock()
+	r.objects++
+	r.mu.Unlock()
+	r.emit(jsonEvent{Event: "skipped", Key: key})
+}
+
+func (r *jsonReporter) Failed(key, stage string, err error) {
+	r.mu.Lock()
+	r.errors++
+	r.mu.Unlock()
+	r.emit(jsonEvent{Event: "failed", Key: key, Stage: stage, Error: err.Error()})
+}
+
+func (r *jsonReporter) Close() {
+	r.mu.Lock()
+	objects, bytesMoved, errs := r.objects, r.bytesMoved, r.errors
+	r.mu.Unlock()
+
+	elapsed := time.Since(r.start)
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(bytesMoved) / elapsed.Seconds()
+	}
+	data, _ := json.Marshal(struct {
+		Event                 string  `json:"event"`
+		Objects               int64   `json:"objects"`
+		Bytes                 int64   `json:"bytes"`
+		Errors                int64   `json:"errors"`
+		ElapsedSeconds        float64 `json:"elapsedSeconds"`
+		ThroughputBytesPerSec float64 `json:"throughputBytesPerSec"`
+	}{
+		Event:                 "summary",
+		Objects:               objects,
+		Bytes:                 bytesMoved,
+		Errors:                errs,
+		ElapsedSeconds:        elapsed.Seconds(),
+		ThroughputBytesPerSec: throughput,
+	})
+	fmt.Println(string(data))
+}
+
+// noopReporter discards per-object events. Transfer errors are still
+// surfaced, since silencing them entirely would make failures invisible.
+type noopReporter struct {
+	start time.Time
+}
+
+func (r *noopReporter) Transferred(key string, size int64) {}
+
+func (r *noopReporter) Skipped(key string) {}
+
+func (r *noopReporter) Failed(key, stage string, err error) {
+	fmt.Println("Error:", stage, key, err)
+}
+
+func (r *noopReporter) Close() {}